@@ -0,0 +1,155 @@
+// Package gitdiff computes the paths that one or more pull request SHAs
+// change relative to a base SHA, via either the git binary or an in-process
+// go-git clone.
+package gitdiff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/openshift-knative/hack/pkg/prowgen"
+)
+
+// Backend computes the set of paths that pullSHAs change relative to baseSha
+// in repo. Shell merges the pulls onto baseSha; GoGit diffs trees without
+// merging.
+type Backend interface {
+	Diff(ctx context.Context, repo prowgen.Repository, baseSha string, pullSHAs []string) ([]string, error)
+}
+
+// Shell runs the diff out-of-process via the git binary, the way prowgen
+// already clones and diffs repos elsewhere in this codebase.
+type Shell struct{}
+
+func (Shell) Diff(ctx context.Context, repo prowgen.Repository, baseSha string, pullSHAs []string) ([]string, error) {
+	if err := prowgen.GitClone(ctx, repo); err != nil {
+		return nil, err
+	}
+	if err := prowgen.GitCheckout(ctx, repo, baseSha); err != nil {
+		return nil, err
+	}
+	for _, sha := range pullSHAs {
+		if err := prowgen.GitFetch(ctx, repo, sha); err != nil {
+			return nil, err
+		}
+		if err := prowgen.GitMerge(ctx, repo, sha); err != nil {
+			return nil, err
+		}
+	}
+	return prowgen.GitDiffNameOnly(ctx, repo, baseSha)
+}
+
+// GoGit computes the diff in-process with go-git, without forking a git
+// binary, by cloning into an in-memory billy filesystem and diffing trees.
+// This works in environments without a git binary (e.g. distroless images).
+// It does not merge: it returns the union of paths that differ between
+// baseSha's tree and each pull SHA's tree, so a path that changed on the
+// base after a pull branched off is reported too.
+//
+// It caches the opened *git.Repository per org/repo, so repeated Diff calls
+// against the same repo in one process reuse the in-memory clone and only
+// fetch the newly needed SHAs, instead of re-cloning from scratch every time.
+// The zero value is ready to use.
+type GoGit struct {
+	mu    sync.Mutex
+	repos map[prowgen.Repository]*git.Repository
+}
+
+func (g *GoGit) Diff(ctx context.Context, repo prowgen.Repository, baseSha string, pullSHAs []string) ([]string, error) {
+	url := fmt.Sprintf("https://github.com/%s/%s.git", repo.Org, repo.Repo)
+
+	r, err := g.repoFor(repo, url)
+	if err != nil {
+		return nil, err
+	}
+
+	shas := append([]string{baseSha}, pullSHAs...)
+	refSpecs := make([]config.RefSpec, len(shas))
+	for i, sha := range shas {
+		refSpecs[i] = config.RefSpec(fmt.Sprintf("+%s:refs/prowgen/%s", sha, sha))
+	}
+	if err := r.FetchContext(ctx, &git.FetchOptions{RemoteName: "origin", RefSpecs: refSpecs, Depth: 1}); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+
+	baseCommit, err := r.CommitObject(plumbing.NewHash(baseSha))
+	if err != nil {
+		return nil, fmt.Errorf("resolve base %s: %w", baseSha, err)
+	}
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changedPaths := make(map[string]bool)
+	for _, sha := range pullSHAs {
+		pullCommit, err := r.CommitObject(plumbing.NewHash(sha))
+		if err != nil {
+			return nil, fmt.Errorf("resolve pull %s: %w", sha, err)
+		}
+		pullTree, err := pullCommit.Tree()
+		if err != nil {
+			return nil, err
+		}
+
+		changes, err := baseTree.Diff(pullTree)
+		if err != nil {
+			return nil, fmt.Errorf("diff %s..%s: %w", baseSha, sha, err)
+		}
+		for _, change := range changes {
+			if change.From.Name != "" {
+				changedPaths[change.From.Name] = true
+			}
+			if change.To.Name != "" {
+				changedPaths[change.To.Name] = true
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(changedPaths))
+	for p := range changedPaths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// repoFor returns the cached in-memory clone of repo, initializing and
+// remembering one on first use.
+func (g *GoGit) repoFor(repo prowgen.Repository, url string) (*git.Repository, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if r, ok := g.repos[repo]; ok {
+		return r, nil
+	}
+
+	r, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		return nil, fmt.Errorf("init in-memory repo for %s: %w", url, err)
+	}
+	if _, err := r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}}); err != nil {
+		return nil, fmt.Errorf("add remote %s: %w", url, err)
+	}
+
+	if g.repos == nil {
+		g.repos = make(map[prowgen.Repository]*git.Repository)
+	}
+	g.repos[repo] = r
+	return r, nil
+}
+
+// Backends maps the -backend flag value to a Backend implementation.
+var Backends = map[string]Backend{
+	"shell": Shell{},
+	"gogit": &GoGit{},
+}
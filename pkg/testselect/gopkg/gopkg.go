@@ -0,0 +1,74 @@
+// Package gopkg builds a reverse (importer) graph for the Go packages in a
+// module, so that a changed .go file can be mapped to every package that
+// transitively depends on it.
+package gopkg
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Graph maps source files to their containing package and tracks, for every
+// package in the module, the set of packages that directly import it.
+type Graph struct {
+	// FileToPackage maps an absolute file path to the import path of the
+	// package that contains it.
+	FileToPackage map[string]string
+	// importedBy maps an import path to the import paths that directly
+	// import it.
+	importedBy map[string][]string
+}
+
+// Load walks the module rooted at dir and builds its Graph.
+func Load(dir string) (*Graph, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("loading packages from %s: %w", dir, err)
+	}
+
+	g := &Graph{
+		FileToPackage: make(map[string]string),
+		importedBy:    make(map[string][]string),
+	}
+
+	for _, pkg := range pkgs {
+		for _, f := range pkg.GoFiles {
+			g.FileToPackage[filepath.Clean(f)] = pkg.PkgPath
+		}
+		for _, imp := range pkg.Imports {
+			g.importedBy[imp.PkgPath] = append(g.importedBy[imp.PkgPath], pkg.PkgPath)
+		}
+	}
+
+	return g, nil
+}
+
+// PackageForFile returns the import path of the package containing file, if
+// any.
+func (g *Graph) PackageForFile(file string) (string, bool) {
+	pkgPath, ok := g.FileToPackage[filepath.Clean(file)]
+	return pkgPath, ok
+}
+
+// TransitiveImporters returns the set of import paths that depend on pkgPath,
+// directly or transitively.
+func (g *Graph) TransitiveImporters(pkgPath string) map[string]bool {
+	seen := make(map[string]bool)
+	var visit func(string)
+	visit = func(p string) {
+		for _, importer := range g.importedBy[p] {
+			if !seen[importer] {
+				seen[importer] = true
+				visit(importer)
+			}
+		}
+	}
+	visit(pkgPath)
+	return seen
+}
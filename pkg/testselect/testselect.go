@@ -14,30 +14,75 @@ import (
 	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/openshift-knative/hack/pkg/prowgen"
+	"github.com/openshift-knative/hack/pkg/testselect/gitdiff"
+	"github.com/openshift-knative/hack/pkg/testselect/gopkg"
 	"gopkg.in/yaml.v2"
 	"k8s.io/test-infra/prow/clonerefs"
 )
 
 const (
 	all = "All"
+
+	// defaultActionAll preserves the historical behaviour: an unknown path
+	// causes every test to run.
+	defaultActionAll = "all"
+	// defaultActionSkip means an unknown path is simply ignored instead of
+	// forcing the full suite.
+	defaultActionSkip = "skip"
+
+	// reasonAll means every test suite was run, because an unknown path was
+	// seen, a ref had no pulls to diff, or no clone refs were available at all.
+	reasonAll = "all"
+	// reasonFiltered means a non-empty, reduced set of tests was selected.
+	reasonFiltered = "filtered"
+	// reasonSkipped means no tests were selected, e.g. because the diff
+	// matched always_skip_paths, default_action: skip ignored every unknown
+	// path, or skip_if_only_changed excluded every matched path.
+	reasonSkipped = "skipped"
 )
 
 // TestSuites holds mapping between file path regular expressions and
 // test suites that cover the paths.
 type TestSuites struct {
 	List []TestSuite `yaml:"testsuites"`
+	// DefaultAction controls what happens when a changed path doesn't match
+	// any suite's RunIfChanged or RunIfPackageChanged. Defaults to "all".
+	DefaultAction string `yaml:"default_action"`
+	// AlwaysSkipPaths is a list of path regular expressions (e.g. \.md$,
+	// ^OWNERS$, ^vendor/). If every changed path matches one of them, no
+	// tests are run at all, regardless of any other suite configuration.
+	AlwaysSkipPaths []string `yaml:"always_skip_paths"`
+	// Prototypes are named TestSuites that other suites in List can extend,
+	// instead of repeating their run_if_changed and tests blocks.
+	Prototypes []TestSuite `yaml:"prototypes"`
+	// Include lists other testsuites.yaml files (relative to this one) to
+	// merge in before resolving extends.
+	Include []string `yaml:"include"`
 }
 
 type TestSuite struct {
 	Name 		 string   `yaml:"name"`
 	RunIfChanged []string `yaml:"run_if_changed"`
+	// RunIfPackageChanged is a list of Go import path globs (e.g.
+	// "./pkg/reconciler/..."). A suite matches if a changed .go file's
+	// package is transitively imported by any matching package.
+	RunIfPackageChanged []string `yaml:"run_if_package_changed"`
+	// SkipIfOnlyChanged is a list of path regular expressions that this
+	// suite ignores entirely, even if they would otherwise match
+	// RunIfChanged or RunIfPackageChanged.
+	SkipIfOnlyChanged []string `yaml:"skip_if_only_changed"`
+	// Extends names a TestSuite in the top-level Prototypes list whose
+	// RunIfChanged and Tests this suite inherits.
+	Extends string `yaml:"extends"`
 	Tests 		 []Test   `yaml:"tests"`
 }
 
@@ -46,15 +91,49 @@ type Test struct {
 	Upstream bool   `yaml:"upstream"`
 }
 
+// RepositoriesConfig lets a batch/multi-ref job point different extra refs
+// at different testsuites.yaml files.
+type RepositoriesConfig struct {
+	Repositories []RepositoryTestSuites `yaml:"repositories"`
+}
+
+type RepositoryTestSuites struct {
+	Org        string `yaml:"org"`
+	Repo       string `yaml:"repo"`
+	TestSuites string `yaml:"testsuites"`
+}
+
+// ChangedPath is a path changed by a pull request, tagged with the
+// repository it was changed in so that batch jobs testing several PRs at
+// once can scope test selection per repo.
+type ChangedPath struct {
+	Repo prowgen.Repository
+	Path string
+}
+
 func Main() {
+	if len(os.Args) > 1 && os.Args[1] == "resolve" {
+		resolveMain(os.Args[2:])
+		return
+	}
+
 	ctx := context.Background()
 
 	ts := flag.String("testsuites", "testsuites.yaml", "Specify yaml file with path-to-testsuite mapping")
+	reposCfg := flag.String("repositories", "", "Specify yaml file mapping org/repo to a testsuites.yaml, for batch jobs spanning multiple repositories")
 	// Clonerefs options as defined in https://github.com/kubernetes/test-infra/blob/master/prow/clonerefs/options.go
 	refs := flag.String("clonerefs", "clonerefs.json", "Specify json file with clonerefs")
 	outFile := flag.String("output", "tests.txt", "Specify name of output file")
+	backendName := flag.String("backend", "shell", "Specify the git diff backend to use: shell or gogit")
+	format := flag.String("format", "lines", "Specify output format: lines, json, or shell")
+	exitCodeOnEmpty := flag.Int("exit-code-on-empty", 0, "If nonzero, exit with this code when no tests are selected, so the calling step can skip the job")
 	flag.Parse()
 
+	backend, ok := gitdiff.Backends[*backendName]
+	if !ok {
+		log.Fatalf("Unknown -backend %q", *backendName)
+	}
+
 	log.Println(*ts, *refs, *outFile)
 
 	inRefs, err := os.ReadFile(*refs)
@@ -67,84 +146,405 @@ func Main() {
 		log.Fatalln("Unmarshal clone refs options", err)
 	}
 
-	inTs, err := os.ReadFile(*ts)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	testSuites := new(TestSuites)
-	if err := yaml.UnmarshalStrict(inTs, testSuites); err != nil {
-		log.Fatalln("Unmarshal test suite mappings", err)
+	var repoConfigs []RepositoryTestSuites
+	if *reposCfg != "" {
+		inRepos, err := os.ReadFile(*reposCfg)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		repositories := new(RepositoriesConfig)
+		if err := yaml.UnmarshalStrict(inRepos, repositories); err != nil {
+			log.Fatalln("Unmarshal repositories config", err)
+		}
+		repoConfigs = repositories.Repositories
 	}
 
-	var tests, paths []string
+	var changedPaths []string
+	testsToRun := make(map[string]bool)
+	matchedSuites := make(map[string]bool)
+	reason := reasonAll
 
-	if len(cloneRefs.GitRefs) == 0 || len(cloneRefs.GitRefs[0].Pulls) == 0 {
+	if len(cloneRefs.GitRefs) == 0 {
 		log.Println(`Clone refs do not include required SHAs. Returning "All".`)
-		tests = []string{ all }
+		testsToRun[all] = true
 	} else {
-		repo := prowgen.Repository{
-			Org:  cloneRefs.GitRefs[0].Org,
-			Repo: cloneRefs.GitRefs[0].Repo,
-		}
-		paths, err = Diff(ctx, repo, cloneRefs.GitRefs[0].BaseSHA, cloneRefs.GitRefs[0].Pulls[0].SHA)
+		changed, unknownRepos, err := Diff(ctx, *cloneRefs, backend)
 		if err != nil {
 			log.Fatalln("Error reading diff", err)
 		}
-		tests, err = filterTests(*testSuites, paths)
+
+		reason = reasonSkipped
+		for repo := range unknownRepos {
+			log.Printf("%s/%s has no Pulls (periodic/postsubmit job). Returning \"All\".", repo.Org, repo.Repo)
+			testsToRun[all] = true
+			reason = combineReasons(reason, reasonAll)
+		}
+		for repo, paths := range groupByRepo(changed) {
+			changedPaths = append(changedPaths, paths...)
+
+			testSuites, err := loadTestSuites(testSuitesFileFor(repo, *ts, repoConfigs))
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			// gopkg.Load (via go/packages) reports file paths as absolute, so
+			// the directory joined with those paths for lookups must be
+			// absolute too.
+			absRepoDir, err := filepath.Abs(repoDir(repo))
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			var graph *gopkg.Graph
+			if usesPackageChanged(*testSuites) {
+				graph, err = gopkg.Load(absRepoDir)
+				if err != nil {
+					log.Fatalln("Error loading Go package graph", err)
+				}
+			}
+
+			result, err := filterTests(*testSuites, paths, absRepoDir, graph)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, t := range result.Tests {
+				testsToRun[t] = true
+			}
+			for _, s := range result.MatchedSuites {
+				matchedSuites[s] = true
+			}
+			reason = combineReasons(reason, result.Reason)
+		}
+	}
+
+	tests := sortedKeys(testsToRun)
+	sort.Strings(changedPaths)
+
+	if err := writeOutput(*outFile, *format, tests, changedPaths, sortedKeys(matchedSuites), reason); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(tests) == 0 && *exitCodeOnEmpty != 0 {
+		os.Exit(*exitCodeOnEmpty)
+	}
+}
+
+// combineReasons merges the reason from one more repo's FilterResult into
+// the running aggregate: "all" wins over everything, "filtered" wins over
+// "skipped".
+func combineReasons(aggregate, next string) string {
+	if aggregate == reasonAll || next == reasonAll {
+		return reasonAll
+	}
+	if aggregate == reasonFiltered || next == reasonFiltered {
+		return reasonFiltered
+	}
+	return reasonSkipped
+}
+
+type jsonOutput struct {
+	Tests         []string `json:"tests"`
+	ChangedPaths  []string `json:"changed_paths"`
+	MatchedSuites []string `json:"matched_suites"`
+	Reason        string   `json:"reason"`
+}
+
+// writeOutput renders tests (and, for the "json" format, the surrounding
+// selection context) to outFile in the requested format.
+func writeOutput(outFile, format string, tests, changedPaths, matchedSuites []string, reason string) error {
+	var content []byte
+	switch format {
+	case "lines":
+		var sb strings.Builder
+		for _, tst := range tests {
+			sb.WriteString(tst + "\n")
+		}
+		content = []byte(sb.String())
+	case "json":
+		out, err := json.Marshal(jsonOutput{
+			Tests:         tests,
+			ChangedPaths:  changedPaths,
+			MatchedSuites: matchedSuites,
+			Reason:        reason,
+		})
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
+		content = out
+	case "shell":
+		content = []byte(fmt.Sprintf("TESTS=%q\n", strings.Join(tests, " ")))
+	default:
+		return fmt.Errorf("unknown -format %q", format)
 	}
 
-	var sb strings.Builder
-	for _, tst := range tests {
-		sb.WriteString(tst + "\n")
+	return os.WriteFile(outFile, content, os.ModePerm)
+}
+
+// resolveMain implements the "testselect resolve" subcommand, which prints
+// the fully-expanded suite set (after resolving include and extends) for
+// debugging a testsuites.yaml.
+func resolveMain(args []string) {
+	fs := flag.NewFlagSet("resolve", flag.ExitOnError)
+	ts := fs.String("testsuites", "testsuites.yaml", "Specify yaml file with path-to-testsuite mapping")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	testSuites, err := loadTestSuites(*ts)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if err := os.WriteFile(*outFile, []byte(sb.String()), os.ModePerm); err != nil {
+	out, err := yaml.Marshal(testSuites)
+	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Print(string(out))
 }
 
-func Diff(ctx context.Context, repo prowgen.Repository, baseSha, sha string) ([]string, error) {
-	if err := prowgen.GitClone(ctx, repo); err != nil {
+// loadTestSuites reads path, merges in any files it includes, and resolves
+// extends against the resulting prototype set.
+func loadTestSuites(path string) (*TestSuites, error) {
+	testSuites, err := readTestSuitesFile(path)
+	if err != nil {
 		return nil, err
 	}
-	if err := prowgen.GitCheckout(ctx, repo, baseSha); err != nil {
-		return nil, err
+
+	for _, include := range testSuites.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		included, err := loadTestSuites(include)
+		if err != nil {
+			return nil, err
+		}
+		testSuites.List = append(included.List, testSuites.List...)
+		testSuites.Prototypes = append(included.Prototypes, testSuites.Prototypes...)
+		testSuites.AlwaysSkipPaths = append(included.AlwaysSkipPaths, testSuites.AlwaysSkipPaths...)
+		if testSuites.DefaultAction == "" {
+			testSuites.DefaultAction = included.DefaultAction
+		}
 	}
-	if err := prowgen.GitFetch(ctx, repo, sha); err != nil {
-		return nil, err
+	testSuites.Include = nil
+
+	if err := resolveExtends(testSuites); err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", path, err)
 	}
-	if err := prowgen.GitMerge(ctx, repo, sha); err != nil {
+
+	return testSuites, nil
+}
+
+func readTestSuitesFile(path string) (*TestSuites, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
 		return nil, err
 	}
-	return prowgen.GitDiffNameOnly(ctx, repo, baseSha)
+	testSuites := new(TestSuites)
+	if err := yaml.UnmarshalStrict(in, testSuites); err != nil {
+		return nil, fmt.Errorf("unmarshal test suite mappings from %s: %w", path, err)
+	}
+	return testSuites, nil
+}
+
+// resolveExtends resolves each suite's extends against testSuites.Prototypes,
+// concatenating RunIfChanged from the parent and merging Tests (child wins
+// on Name collisions).
+func resolveExtends(testSuites *TestSuites) error {
+	prototypes := make(map[string]TestSuite, len(testSuites.Prototypes))
+	for _, p := range testSuites.Prototypes {
+		prototypes[p.Name] = p
+	}
+
+	for i, suite := range testSuites.List {
+		if suite.Extends == "" {
+			continue
+		}
+		parent, ok := prototypes[suite.Extends]
+		if !ok {
+			return fmt.Errorf("suite %q extends unknown prototype %q", suite.Name, suite.Extends)
+		}
+		testSuites.List[i] = mergeSuite(parent, suite)
+	}
+	return nil
+}
+
+func mergeSuite(parent, child TestSuite) TestSuite {
+	merged := child
+	merged.Extends = ""
+	merged.RunIfChanged = append(append([]string{}, parent.RunIfChanged...), child.RunIfChanged...)
+	merged.RunIfPackageChanged = append(append([]string{}, parent.RunIfPackageChanged...), child.RunIfPackageChanged...)
+	merged.SkipIfOnlyChanged = append(append([]string{}, parent.SkipIfOnlyChanged...), child.SkipIfOnlyChanged...)
+	merged.Tests = mergeTests(parent.Tests, child.Tests)
+	return merged
+}
+
+// mergeTests concatenates parentTests and childTests, with a Test in
+// childTests overriding a parent Test of the same Name in place.
+func mergeTests(parentTests, childTests []Test) []Test {
+	byName := make(map[string]Test, len(parentTests)+len(childTests))
+	var order []string
+	for _, t := range parentTests {
+		byName[t.Name] = t
+		order = append(order, t.Name)
+	}
+	for _, t := range childTests {
+		if _, exists := byName[t.Name]; !exists {
+			order = append(order, t.Name)
+		}
+		byName[t.Name] = t
+	}
+
+	merged := make([]Test, 0, len(order))
+	for _, name := range order {
+		merged = append(merged, byName[name])
+	}
+	return merged
+}
+
+// testSuitesFileFor returns the testsuites.yaml that applies to repo,
+// preferring an explicit entry in repoConfigs and falling back to the
+// default file used for single-PR jobs.
+func testSuitesFileFor(repo prowgen.Repository, defaultFile string, repoConfigs []RepositoryTestSuites) string {
+	for _, rc := range repoConfigs {
+		if rc.Org == repo.Org && rc.Repo == repo.Repo {
+			return rc.TestSuites
+		}
+	}
+	return defaultFile
+}
+
+func groupByRepo(changed []ChangedPath) map[prowgen.Repository][]string {
+	byRepo := make(map[prowgen.Repository][]string)
+	for _, c := range changed {
+		byRepo[c.Repo] = append(byRepo[c.Repo], c.Path)
+	}
+	return byRepo
 }
 
-func filterTests(testSuites TestSuites, paths []string) ([]string, error) {
+// Diff merges every pull listed for each ref in opts on top of its base SHA,
+// using backend, and returns the union of changed paths across all refs.
+// This covers both Prow batch presubmits (several pulls on one ref) and jobs
+// with multiple extra refs (several repos).
+//
+// A GitRef with no Pulls isn't a PR diff at all (periodic and postsubmit jobs
+// carry only a BaseSHA), so there's nothing to diff; such repos are returned
+// separately in unknownRepos rather than as an (incorrectly empty) diff, so
+// callers can treat them as "run everything" instead of "no changes".
+func Diff(ctx context.Context, opts clonerefs.Options, backend gitdiff.Backend) (changed []ChangedPath, unknownRepos map[prowgen.Repository]bool, err error) {
+	unknownRepos = make(map[prowgen.Repository]bool)
+	for _, gitRef := range opts.GitRefs {
+		repo := prowgen.Repository{
+			Org:  gitRef.Org,
+			Repo: gitRef.Repo,
+		}
+
+		if len(gitRef.Pulls) == 0 {
+			unknownRepos[repo] = true
+			continue
+		}
+
+		pullSHAs := make([]string, len(gitRef.Pulls))
+		for i, pull := range gitRef.Pulls {
+			pullSHAs[i] = pull.SHA
+		}
+
+		paths, err := backend.Diff(ctx, repo, gitRef.BaseSHA, pullSHAs)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, path := range paths {
+			changed = append(changed, ChangedPath{Repo: repo, Path: path})
+		}
+	}
+	return changed, unknownRepos, nil
+}
+
+// repoDir returns the directory a repository is cloned into, following the
+// org/repo layout used throughout prowgen.
+func repoDir(repo prowgen.Repository) string {
+	return filepath.Join(repo.Org, repo.Repo)
+}
+
+// usesPackageChanged reports whether any suite relies on RunIfPackageChanged,
+// in which case the (comparatively expensive) Go package graph must be built.
+func usesPackageChanged(testSuites TestSuites) bool {
+	for _, suite := range testSuites.List {
+		if len(suite.RunIfPackageChanged) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterResult is the outcome of matching a repo's changed paths against its
+// TestSuites.
+type FilterResult struct {
+	Tests         []string
+	MatchedSuites []string
+	Reason        string
+}
+
+func filterTests(testSuites TestSuites, paths []string, repoDir string, graph *gopkg.Graph) (*FilterResult, error) {
+	allSkipped, err := allPathsMatch(testSuites.AlwaysSkipPaths, paths)
+	if err != nil {
+		return nil, err
+	}
+	if allSkipped {
+		return &FilterResult{Reason: reasonSkipped}, nil
+	}
+
 	testsToRun := make(map[string]bool)
+	matchedSuites := make(map[string]bool)
 	for _, path := range paths {
-		matchAny := false
+		// recognized tracks whether any suite's RunIfChanged/RunIfPackageChanged
+		// pattern set claims this path, regardless of whether that suite then
+		// opts out of it via SkipIfOnlyChanged. A deliberately-skipped path is
+		// still a known path and must not fall through to the unknown-path
+		// handling below.
+		recognized := false
 		for _, suite := range testSuites.List {
+			matched := false
 			for _, pathRegex := range suite.RunIfChanged {
-				matched, err := regexp.MatchString(pathRegex, path)
+				m, err := regexp.MatchString(pathRegex, path)
 				if err != nil {
 					return nil, err
 				}
-				if matched {
-					matchAny = true
-					for _, test := range suite.Tests {
-						testsToRun[test.Name] = true
-					}
+				if m {
+					matched = true
+					break
 				}
 			}
+			if !matched {
+				if m, err := matchesPackageChanged(suite, path, repoDir, graph); err != nil {
+					return nil, err
+				} else if m {
+					matched = true
+				}
+			}
+			if !matched {
+				continue
+			}
+			recognized = true
+
+			if skip, err := pathMatchesAny(suite.SkipIfOnlyChanged, path); err != nil {
+				return nil, err
+			} else if skip {
+				continue
+			}
+
+			matchedSuites[suite.Name] = true
+			for _, test := range suite.Tests {
+				testsToRun[test.Name] = true
+			}
 		}
-		// If the path doesn't match any path expressions then it is unknown
-		// path and all test suites should be run.
-		if !matchAny {
-			return []string{ all }, nil
+		// If the path doesn't match any path expressions then it is unknown.
+		// By default all test suites should be run, unless default_action
+		// says the path should simply be skipped instead.
+		if !recognized {
+			if testSuites.DefaultAction == defaultActionSkip {
+				continue
+			}
+			return &FilterResult{Tests: []string{all}, Reason: reasonAll}, nil
 		}
 	}
 
@@ -161,7 +561,103 @@ func filterTests(testSuites TestSuites, paths []string) ([]string, error) {
 		}
 	}
 
-	return sortedKeys(testsToRun), nil
+	reason := reasonFiltered
+	if len(testsToRun) == 0 {
+		reason = reasonSkipped
+	}
+
+	return &FilterResult{
+		Tests:         sortedKeys(testsToRun),
+		MatchedSuites: sortedKeys(matchedSuites),
+		Reason:        reason,
+	}, nil
+}
+
+// pathMatchesAny reports whether path matches any of the given path regular
+// expressions.
+func pathMatchesAny(patterns []string, path string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := regexp.MatchString(pattern, path)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// allPathsMatch reports whether every path matches at least one of patterns.
+// An empty patterns list never matches anything.
+func allPathsMatch(patterns []string, paths []string) (bool, error) {
+	if len(patterns) == 0 {
+		return false, nil
+	}
+	for _, path := range paths {
+		matched, err := pathMatchesAny(patterns, path)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchesPackageChanged reports whether path is a .go file whose package, or
+// any package that transitively imports it, matches one of suite's
+// RunIfPackageChanged globs.
+func matchesPackageChanged(suite TestSuite, path, repoDir string, graph *gopkg.Graph) (bool, error) {
+	if len(suite.RunIfPackageChanged) == 0 || graph == nil || !strings.HasSuffix(path, ".go") {
+		return false, nil
+	}
+
+	pkgPath, ok := graph.PackageForFile(filepath.Join(repoDir, path))
+	if !ok {
+		return false, nil
+	}
+
+	// The module path is pkgPath without the changed file's directory
+	// relative to repoDir; relative globs are rooted there.
+	modulePath := strings.TrimSuffix(pkgPath, "/"+filepath.ToSlash(filepath.Dir(path)))
+
+	affected := graph.TransitiveImporters(pkgPath)
+	affected[pkgPath] = true
+
+	for _, glob := range suite.RunIfPackageChanged {
+		re, err := packageGlobToRegexp(glob, modulePath)
+		if err != nil {
+			return false, err
+		}
+		for p := range affected {
+			if re.MatchString(p) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// packageGlobToRegexp compiles an import path glob such as
+// "./pkg/reconciler/..." into a regular expression matching full import
+// paths, where "..." matches any suffix (mirroring `go list` patterns).
+// A glob starting with "./" is taken relative to modulePath.
+func packageGlobToRegexp(glob, modulePath string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(glob, "./") {
+		glob = modulePath + "/" + strings.TrimPrefix(glob, "./")
+	}
+	parts := strings.Split(glob, "...")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	re := strings.Join(parts, ".*")
+	// As with `go list`, a trailing "/..." also matches the package itself.
+	if strings.HasSuffix(re, "/.*") {
+		re = strings.TrimSuffix(re, "/.*") + "(/.*)?"
+	}
+	return regexp.Compile("^" + re + "$")
 }
 
 func sortedKeys(stringMap map[string]bool) []string {
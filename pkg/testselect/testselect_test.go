@@ -0,0 +1,65 @@
+package testselect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift-knative/hack/pkg/testselect/gopkg"
+)
+
+// writeTestModule lays out a tiny two-package module on disk, where pkg/b
+// imports pkg/a, and returns its root directory.
+func writeTestModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":     "module example.com/testmodule\n\ngo 1.21\n",
+		"pkg/a/a.go": "package a\n\nfunc A() int { return 1 }\n",
+		"pkg/b/b.go": "package b\n\nimport \"example.com/testmodule/pkg/a\"\n\nfunc B() int { return a.A() }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMatchesPackageChanged(t *testing.T) {
+	dir := writeTestModule(t)
+
+	graph, err := gopkg.Load(dir)
+	if err != nil {
+		t.Fatalf("gopkg.Load(%q) = %v", dir, err)
+	}
+
+	suite := TestSuite{
+		Name:                "b",
+		RunIfPackageChanged: []string{"example.com/testmodule/pkg/b"},
+	}
+
+	// Changing pkg/a should match the suite watching pkg/b, since b imports
+	// a transitively.
+	matched, err := matchesPackageChanged(suite, "pkg/a/a.go", dir, graph)
+	if err != nil {
+		t.Fatalf("matchesPackageChanged = %v", err)
+	}
+	if !matched {
+		t.Errorf("matchesPackageChanged(pkg/a/a.go) = false, want true (pkg/b imports pkg/a)")
+	}
+
+	// A path outside the module's packages shouldn't match anything.
+	matched, err = matchesPackageChanged(suite, "README.md", dir, graph)
+	if err != nil {
+		t.Fatalf("matchesPackageChanged = %v", err)
+	}
+	if matched {
+		t.Errorf("matchesPackageChanged(README.md) = true, want false")
+	}
+}